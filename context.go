@@ -0,0 +1,226 @@
+// MIT License
+//
+// Copyright (c) 2025 Abdullah Fawwaz Qudamah
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pbkdf
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"runtime"
+)
+
+// iterationChunkSize is how many PBKDF2 iterations pbkdf2Chunked runs before
+// it re-checks ctx, so a cancellation is noticed promptly instead of only
+// after the whole (potentially expensive) derivation finishes.
+const iterationChunkSize = 1024
+
+// pbkdf2Chunked derives a key the same way golang.org/x/crypto/pbkdf2.Key
+// does, but checks ctx for cancellation every iterationChunkSize iterations
+// so HashContext and VerifyContext can bound how long a single attempt is
+// allowed to run.
+func pbkdf2Chunked(ctx context.Context, password, salt []byte, iterations, keyLen int, h func() hash.Hash) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// keyLen ultimately drives the size of the dk allocation below, which
+	// happens up front, before the per-chunk ctx check ever runs; bound it
+	// here too so a caller that builds Params from untrusted input without
+	// going through decodeHash (which already enforces maxDecodedKeyLen)
+	// can't force an unbounded allocation.
+	if keyLen > maxDecodedKeyLen {
+		return nil, errors.New("pbkdf: KeyLen exceeds maximum")
+	}
+
+	prf := hmac.New(h, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var blockIndex [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		blockIndex[0] = byte(block >> 24)
+		blockIndex[1] = byte(block >> 16)
+		blockIndex[2] = byte(block >> 8)
+		blockIndex[3] = byte(block)
+		prf.Write(blockIndex[:])
+		t := prf.Sum(nil)
+		copy(u, t)
+
+		for n := 2; n <= iterations; n++ {
+			if (n-1)%iterationChunkSize == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+		dk = append(dk, t...)
+	}
+
+	return dk[:keyLen], nil
+}
+
+// HashContext is Hash with a context, so callers can bound the CPU time
+// spent deriving the key on a single request (for example, to defend
+// against a client that submits deliberately expensive parameters).
+// Cancellation is checked between chunks of iterations, not instantly.
+func (p Params) HashContext(ctx context.Context, password []byte) (string, error) {
+	if p.Iterations == 0 {
+		p.Iterations = DefaultParams.Iterations
+	}
+	if p.KeyLen == 0 {
+		p.KeyLen = DefaultParams.KeyLen
+	}
+	if p.SaltLen == 0 {
+		p.SaltLen = DefaultParams.SaltLen
+	}
+	if p.HashFunc == nil {
+		p.HashFunc = DefaultParams.HashFunc
+	}
+
+	tag, ok := tagForHash(p.HashFunc)
+	if !ok {
+		return "", errors.New("pbkdf: HashFunc is not registered, call RegisterHash first")
+	}
+
+	if p.Encoding == EncodingUTF8 {
+		return "", errors.New("pbkdf: EncodingUTF8 is only valid for SaltEncoding, not Encoding")
+	}
+
+	var salt []byte
+	var err error
+	if p.SaltEncoding == EncodingUTF8 {
+		salt, err = GenerateAlphanumericSalt(p.SaltLen)
+	} else {
+		salt, err = GenerateSalt(p.SaltLen)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	dk, err := pbkdf2Chunked(ctx, password, salt, p.Iterations, p.KeyLen, p.HashFunc)
+	if err != nil {
+		return "", err
+	}
+
+	params := fmt.Sprintf("i=%d,l=%d", p.Iterations, p.KeyLen)
+	if t := p.Encoding.tag(); t != "" {
+		params += ",e=" + t
+	}
+	if t := p.SaltEncoding.tag(); t != "" {
+		params += ",se=" + t
+	}
+
+	encodedSalt := p.SaltEncoding.encode(salt)
+	encodedHash := p.Encoding.encode(dk)
+
+	return fmt.Sprintf("$%s$%s$%s$%s", tag, params, encodedSalt, encodedHash), nil
+}
+
+// VerifyContext is Verify with a context, so callers can bound the CPU time
+// spent on a single verification attempt.
+func VerifyContext(ctx context.Context, password []byte, encodedHash string) (bool, error) {
+	d, err := decodeHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	dk, err := pbkdf2Chunked(ctx, password, d.salt, d.iterations, d.keyLen, d.hashFunc)
+	if err != nil {
+		return false, err
+	}
+
+	if subtle.ConstantTimeCompare(dk, d.key) == 1 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// DefaultMaxPasswordLen is the MaxPasswordLen used by HashReader when
+// Params.MaxPasswordLen is 0.
+const DefaultMaxPasswordLen = 1 << 20 // 1 MiB
+
+// HashReader streams a password from r into an internal buffer, capped at
+// p.MaxPasswordLen bytes (DefaultMaxPasswordLen if unset), and hashes it
+// with p.HashContext. This is for passphrases that don't already live in a
+// []byte, such as one read from a disk-encryption key file. The buffer is
+// zeroed before HashReader returns.
+func HashReader(ctx context.Context, r io.Reader, p Params) (string, error) {
+	maxLen := p.MaxPasswordLen
+	if maxLen <= 0 {
+		maxLen = DefaultMaxPasswordLen
+	}
+
+	// Capacity is fixed at maxLen up front so append never reallocates and
+	// copies into a fresh, unwiped backing array partway through.
+	password := make([]byte, 0, maxLen)
+	chunk := make([]byte, 4096)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			if len(password)+n > maxLen {
+				wipe(password)
+				wipe(chunk)
+				return "", fmt.Errorf("pbkdf: password exceeds MaxPasswordLen (%d bytes)", maxLen)
+			}
+			password = append(password, chunk[:n]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			wipe(password)
+			wipe(chunk)
+			return "", err
+		}
+	}
+	wipe(chunk)
+	defer wipe(password)
+
+	return p.HashContext(ctx, password)
+}
+
+// wipe zeroes b in place. runtime.KeepAlive ensures the compiler can't prove
+// the writes are dead and drop them, which it otherwise could once b is no
+// longer read from afterward.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	runtime.KeepAlive(b)
+}
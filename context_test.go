@@ -0,0 +1,127 @@
+// MIT License
+//
+// Copyright (c) 2025 Abdullah Fawwaz Qudamah
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pbkdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHashContextAndVerifyContext(t *testing.T) {
+	password := []byte("securepassword")
+	params := Params{Iterations: 2000, KeyLen: 32, SaltLen: 16, HashFunc: SHA256Params.HashFunc}
+
+	hash, err := params.HashContext(context.Background(), password)
+	if err != nil {
+		t.Fatalf("HashContext failed: %v", err)
+	}
+
+	match, err := VerifyContext(context.Background(), password, hash)
+	if err != nil {
+		t.Fatalf("VerifyContext failed: %v", err)
+	}
+	if !match {
+		t.Error("VerifyContext returned false for correct password")
+	}
+}
+
+func TestHashContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	params := Params{Iterations: 1 << 20, KeyLen: 32, SaltLen: 16, HashFunc: SHA256Params.HashFunc}
+
+	_, err := params.HashContext(ctx, []byte("securepassword"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("HashContext error = %v, want context.Canceled", err)
+	}
+}
+
+func TestVerifyContextCancellation(t *testing.T) {
+	params := Params{Iterations: 1 << 20, KeyLen: 32, SaltLen: 16, HashFunc: SHA256Params.HashFunc}
+	hash, err := params.Hash([]byte("securepassword"))
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = VerifyContext(ctx, []byte("securepassword"), hash)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("VerifyContext error = %v, want context.Canceled", err)
+	}
+}
+
+func TestHashReader(t *testing.T) {
+	password := []byte("securepassword")
+	r := bytes.NewReader(password)
+	params := Params{Iterations: 2000, KeyLen: 32, SaltLen: 16, HashFunc: SHA256Params.HashFunc}
+
+	hash, err := HashReader(context.Background(), r, params)
+	if err != nil {
+		t.Fatalf("HashReader failed: %v", err)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for the password read from HashReader's reader")
+	}
+}
+
+func TestHashReaderLargePassword(t *testing.T) {
+	// Larger than the reader's internal 4096-byte read chunk, to exercise
+	// more than one Read call into the password buffer.
+	password := bytes.Repeat([]byte("x"), 10000)
+	r := bytes.NewReader(password)
+	params := Params{Iterations: 2000, KeyLen: 32, SaltLen: 16, HashFunc: SHA256Params.HashFunc}
+
+	hash, err := HashReader(context.Background(), r, params)
+	if err != nil {
+		t.Fatalf("HashReader failed: %v", err)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for a password spanning multiple reads")
+	}
+}
+
+func TestHashReaderExceedsMaxPasswordLen(t *testing.T) {
+	r := strings.NewReader("this password is far too long for the configured cap")
+	params := Params{MaxPasswordLen: 8}
+
+	_, err := HashReader(context.Background(), r, params)
+	if err == nil {
+		t.Error("Expected error for a password exceeding MaxPasswordLen")
+	}
+}
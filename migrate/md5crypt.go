@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2025 Abdullah Fawwaz Qudamah
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package migrate
+
+import "crypto/md5"
+
+// md5CryptAlphabet is the base64-like alphabet used by crypt(3)'s md5-crypt
+// output, least-significant-bit first.
+const md5CryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// md5Crypt implements the FreeBSD/glibc md5-crypt algorithm and returns the
+// full "$1$<salt>$<hash>" string for password under salt, so it can be
+// compared directly against a stored MCF hash.
+func md5Crypt(password, salt []byte) string {
+	const magic = "$1$"
+
+	h := md5.New()
+	h.Write(password)
+	h.Write([]byte(magic))
+	h.Write(salt)
+
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write(salt)
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	for pl := len(password); pl > 0; pl -= 16 {
+		n := pl
+		if n > 16 {
+			n = 16
+		}
+		h.Write(altSum[:n])
+	}
+
+	for i := len(password); i != 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+
+	sum := h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		c := md5.New()
+		if i&1 != 0 {
+			c.Write(password)
+		} else {
+			c.Write(sum)
+		}
+		if i%3 != 0 {
+			c.Write(salt)
+		}
+		if i%7 != 0 {
+			c.Write(password)
+		}
+		if i&1 != 0 {
+			c.Write(sum)
+		} else {
+			c.Write(password)
+		}
+		sum = c.Sum(nil)
+	}
+
+	var out []byte
+	out = append(out, to64(uint32(sum[0])<<16|uint32(sum[6])<<8|uint32(sum[12]), 4)...)
+	out = append(out, to64(uint32(sum[1])<<16|uint32(sum[7])<<8|uint32(sum[13]), 4)...)
+	out = append(out, to64(uint32(sum[2])<<16|uint32(sum[8])<<8|uint32(sum[14]), 4)...)
+	out = append(out, to64(uint32(sum[3])<<16|uint32(sum[9])<<8|uint32(sum[15]), 4)...)
+	out = append(out, to64(uint32(sum[4])<<16|uint32(sum[10])<<8|uint32(sum[5]), 4)...)
+	out = append(out, to64(uint32(sum[11]), 2)...)
+
+	return magic + string(salt) + "$" + string(out)
+}
+
+// to64 encodes the low n*6 bits of v into md5CryptAlphabet characters,
+// least-significant group first.
+func to64(v uint32, n int) []byte {
+	out := make([]byte, 0, n)
+	for ; n > 0; n-- {
+		out = append(out, md5CryptAlphabet[v&0x3f])
+		v >>= 6
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2025 Abdullah Fawwaz Qudamah
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package migrate verifies passwords against legacy hash formats so
+// applications can move a mixed password database onto pbkdf's canonical
+// $pbkdf2-sha256$ format without forcing every user to reset their password
+// at once. Support is limited to recognizing and checking the old formats;
+// callers are expected to re-hash with pbkdf.Hash after a successful login.
+package migrate
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+
+	pbkdf "github.com/fawwazid/go-pbkdf"
+)
+
+// passlibEncoding is Passlib's "adapted base64": the standard alphabet with
+// '+' replaced by '.', and no padding.
+var passlibEncoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+// Verify checks password against encoded, which may be in any of the
+// formats this package recognizes: this module's own tagged PBKDF2 format,
+// Django's pbkdf2_sha256, Passlib's $pbkdf2-sha256$, MCF md5-crypt ($1$), or
+// bcrypt ($2a$/$2b$/$2y$). needsUpgrade is true whenever encoded is not
+// already in pbkdf's native format, signaling that the caller should
+// rewrite storage with pbkdf.Hash after a successful verification.
+func Verify(password []byte, encoded string) (ok bool, needsUpgrade bool, err error) {
+	switch {
+	case isNative(encoded):
+		ok, err = pbkdf.Verify(password, encoded)
+		return ok, false, err
+	case strings.HasPrefix(encoded, "pbkdf2_sha256$"):
+		ok, err = verifyDjango(password, encoded)
+		return ok, true, err
+	case strings.HasPrefix(encoded, "$pbkdf2-sha256$"):
+		ok, err = verifyPasslib(password, encoded)
+		return ok, true, err
+	case strings.HasPrefix(encoded, "$1$"):
+		ok, err = verifyMD5Crypt(password, encoded)
+		return ok, true, err
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		ok, err = verifyBcrypt(password, encoded)
+		return ok, true, err
+	default:
+		return false, false, errors.New("migrate: unrecognized hash format")
+	}
+}
+
+// isNative reports whether encoded is already in pbkdf's own
+// "$<tag>$i=...,l=...$salt$hash" format, as opposed to Passlib's
+// "$pbkdf2-sha256$<iter>$salt$hash", which shares the same tag but not the
+// "i="/"l=" parameter syntax.
+func isNative(encoded string) bool {
+	parts := strings.SplitN(encoded, "$", 4)
+	if len(parts) < 3 || !strings.HasPrefix(parts[1], "pbkdf2-") {
+		return false
+	}
+	return strings.HasPrefix(parts[2], "i=")
+}
+
+// verifyDjango checks Django's pbkdf2_sha256$<iter>$<salt>$<b64hash> format.
+// The salt is stored as raw UTF-8 and the hash as standard, padded base64.
+func verifyDjango(password []byte, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 {
+		return false, errors.New("migrate: malformed django hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false, errors.New("migrate: malformed django hash")
+	}
+
+	want, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, errors.New("migrate: malformed django hash")
+	}
+
+	dk := pbkdf2.Key(password, []byte(parts[2]), iterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(dk, want) == 1, nil
+}
+
+// verifyPasslib checks Passlib's $pbkdf2-sha256$<iter>$<salt>$<hash> format,
+// whose salt and hash are encoded with Passlib's unpadded "./" alphabet.
+func verifyPasslib(password []byte, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 || parts[1] != "pbkdf2-sha256" {
+		return false, errors.New("migrate: malformed passlib hash")
+	}
+
+	iterations, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return false, errors.New("migrate: malformed passlib hash")
+	}
+
+	salt, err := passlibEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false, errors.New("migrate: malformed passlib hash")
+	}
+
+	want, err := passlibEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errors.New("migrate: malformed passlib hash")
+	}
+
+	dk := pbkdf2.Key(password, salt, iterations, len(want), sha256.New)
+	return subtle.ConstantTimeCompare(dk, want) == 1, nil
+}
+
+// verifyBcrypt checks an MCF $2a$/$2b$/$2y$ bcrypt hash.
+func verifyBcrypt(password []byte, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), password)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return false, errors.New("migrate: malformed bcrypt hash")
+}
+
+// verifyMD5Crypt checks an MCF $1$ (md5-crypt) hash.
+func verifyMD5Crypt(password []byte, encoded string) (bool, error) {
+	parts := strings.SplitN(encoded, "$", 4)
+	if len(parts) != 4 || parts[1] != "1" {
+		return false, errors.New("migrate: malformed md5-crypt hash")
+	}
+
+	computed := md5Crypt(password, []byte(parts[2]))
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(encoded)) == 1, nil
+}
@@ -0,0 +1,177 @@
+// MIT License
+//
+// Copyright (c) 2025 Abdullah Fawwaz Qudamah
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+
+	pbkdf "github.com/fawwazid/go-pbkdf"
+)
+
+func TestVerifyDjango(t *testing.T) {
+	password := []byte("securepassword")
+	encoded, err := verifyableDjangoHash(password, 20000)
+	if err != nil {
+		t.Fatalf("failed to build django hash: %v", err)
+	}
+
+	ok, needsUpgrade, err := Verify(password, encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !ok {
+		t.Error("Verify returned false for correct password")
+	}
+	if !needsUpgrade {
+		t.Error("Verify returned needsUpgrade=false for a django hash")
+	}
+
+	ok, _, err = Verify([]byte("wrongpassword"), encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if ok {
+		t.Error("Verify returned true for wrong password")
+	}
+}
+
+func TestVerifyPasslib(t *testing.T) {
+	password := []byte("securepassword")
+	encoded, err := verifyablePasslibHash(password, 29000)
+	if err != nil {
+		t.Fatalf("failed to build passlib hash: %v", err)
+	}
+
+	ok, needsUpgrade, err := Verify(password, encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !ok {
+		t.Error("Verify returned false for correct password")
+	}
+	if !needsUpgrade {
+		t.Error("Verify returned needsUpgrade=false for a passlib hash")
+	}
+
+	ok, _, err = Verify([]byte("wrongpassword"), encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if ok {
+		t.Error("Verify returned true for wrong password")
+	}
+}
+
+func TestVerifyBcrypt(t *testing.T) {
+	password := []byte("securepassword")
+	hashed, err := bcrypt.GenerateFromPassword(password, bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("failed to build bcrypt hash: %v", err)
+	}
+
+	ok, needsUpgrade, err := Verify(password, string(hashed))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !ok {
+		t.Error("Verify returned false for correct password")
+	}
+	if !needsUpgrade {
+		t.Error("Verify returned needsUpgrade=false for a bcrypt hash")
+	}
+}
+
+func TestVerifyMD5Crypt(t *testing.T) {
+	password := []byte("securepassword")
+	encoded := md5Crypt(password, []byte("abcdefgh"))
+
+	ok, needsUpgrade, err := Verify(password, encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !ok {
+		t.Error("Verify returned false for correct password")
+	}
+	if !needsUpgrade {
+		t.Error("Verify returned needsUpgrade=false for an md5-crypt hash")
+	}
+
+	ok, _, err = Verify([]byte("wrongpassword"), encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if ok {
+		t.Error("Verify returned true for wrong password")
+	}
+}
+
+func TestVerifyNative(t *testing.T) {
+	password := []byte("securepassword")
+	encoded, err := pbkdf.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	ok, needsUpgrade, err := Verify(password, encoded)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !ok {
+		t.Error("Verify returned false for correct password")
+	}
+	if needsUpgrade {
+		t.Error("Verify returned needsUpgrade=true for the native format")
+	}
+}
+
+func TestVerifyUnrecognizedFormat(t *testing.T) {
+	_, _, err := Verify([]byte("password"), "not-a-recognized-hash")
+	if err == nil {
+		t.Error("Expected error for unrecognized hash format")
+	}
+}
+
+// verifyableDjangoHash builds a pbkdf2_sha256$ hash the way Django would,
+// for use as test fixture input to Verify.
+func verifyableDjangoHash(password []byte, iterations int) (string, error) {
+	salt := []byte("testsalt123456")
+	dk := pbkdf2.Key(password, salt, iterations, 32, sha256.New)
+	b64Hash := base64.StdEncoding.EncodeToString(dk)
+	return "pbkdf2_sha256$" + strconv.Itoa(iterations) + "$" + string(salt) + "$" + b64Hash, nil
+}
+
+// verifyablePasslibHash builds a $pbkdf2-sha256$ hash the way Passlib would,
+// for use as test fixture input to Verify.
+func verifyablePasslibHash(password []byte, iterations int) (string, error) {
+	salt := []byte("testsalt123456")
+	dk := pbkdf2.Key(password, salt, iterations, 32, sha256.New)
+	encodedSalt := passlibEncoding.EncodeToString(salt)
+	encodedHash := passlibEncoding.EncodeToString(dk)
+	return "$pbkdf2-sha256$" + strconv.Itoa(iterations) + "$" + encodedSalt + "$" + encodedHash, nil
+}
@@ -23,35 +23,215 @@
 package pbkdf
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha1"
 	"crypto/sha256"
-	"crypto/subtle"
+	"crypto/sha512"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"hash"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+)
 
-	"golang.org/x/crypto/pbkdf2"
+// hashRegistry maps an algorithm tag, as it appears in the encoded hash
+// string (e.g. "pbkdf2-sha256"), to the HMAC primitive used to derive the
+// key. It is pre-populated with the common constructors and can be extended
+// with RegisterHash. hashRegistryOrder records registration order, oldest
+// first, so tagForHash has a deterministic tie-break when two tags share the
+// same underlying constructor.
+var (
+	hashRegistryMu sync.RWMutex
+	hashRegistry   = map[string]func() hash.Hash{
+		"pbkdf2-sha1":   sha1.New,
+		"pbkdf2-sha256": sha256.New,
+		"pbkdf2-sha384": sha512.New384,
+		"pbkdf2-sha512": sha512.New,
+	}
+	hashRegistryOrder = []string{"pbkdf2-sha1", "pbkdf2-sha256", "pbkdf2-sha384", "pbkdf2-sha512"}
 )
 
-// DefaultParams are the recommended parameters for PBKDF2 as per NIST SP 800-132.
-// NIST recommends a salt length of at least 128 bits (16 bytes).
-// We use 120,000 iterations for SHA-256 to meet modern security standards (2025).
-var DefaultParams = Params{
+// RegisterHash makes an additional HMAC primitive available under tag, both
+// for Params.Hash (when Params.HashFunc is set to fn) and for Verify, which
+// looks up tag from the encoded hash string. It is typically called from an
+// init function before any hashing or verification takes place.
+//
+// fn should not be a constructor already registered under another tag:
+// tagForHash resolves a HashFunc back to its tag by comparing constructors,
+// not tags, so two tags sharing one constructor are indistinguishable to it
+// and it resolves the ambiguity by preferring whichever tag was registered
+// first, which is rarely what's intended. Register a distinct wrapper
+// function instead, e.g. func() hash.Hash { return sha256.New() }.
+func RegisterHash(tag string, fn func() hash.Hash) {
+	hashRegistryMu.Lock()
+	defer hashRegistryMu.Unlock()
+	if _, exists := hashRegistry[tag]; !exists {
+		hashRegistryOrder = append(hashRegistryOrder, tag)
+	}
+	hashRegistry[tag] = fn
+}
+
+// lookupHash resolves an algorithm tag to its HMAC constructor.
+func lookupHash(tag string) (func() hash.Hash, bool) {
+	hashRegistryMu.RLock()
+	defer hashRegistryMu.RUnlock()
+	fn, ok := hashRegistry[tag]
+	return fn, ok
+}
+
+// tagForHash resolves an HMAC constructor back to its registered algorithm
+// tag, so Hash can stamp the right value into the encoded string. Function
+// values can't be compared with ==, so identity is determined by comparing
+// the underlying function pointers via reflection. If more than one tag
+// shares the same constructor, the one registered earliest wins; see the
+// RegisterHash doc comment for why that situation is best avoided.
+func tagForHash(fn func() hash.Hash) (string, bool) {
+	if fn == nil {
+		return "", false
+	}
+	target := reflect.ValueOf(fn).Pointer()
+
+	hashRegistryMu.RLock()
+	defer hashRegistryMu.RUnlock()
+	for _, tag := range hashRegistryOrder {
+		if reflect.ValueOf(hashRegistry[tag]).Pointer() == target {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// SHA256Params are the recommended parameters for PBKDF2-HMAC-SHA256 as per
+// NIST SP 800-132. NIST recommends a salt length of at least 128 bits (16
+// bytes). We use 120,000 iterations for SHA-256 to meet modern security
+// standards (2025).
+var SHA256Params = Params{
 	Iterations: 120000,
 	KeyLen:     32,
 	SaltLen:    16,
 	HashFunc:   sha256.New,
 }
 
+// SHA512Params are the recommended parameters for PBKDF2-HMAC-SHA512. The
+// larger output size of SHA-512 allows a longer derived key while keeping the
+// same iteration count as SHA256Params.
+var SHA512Params = Params{
+	Iterations: 120000,
+	KeyLen:     64,
+	SaltLen:    16,
+	HashFunc:   sha512.New,
+}
+
+// DefaultParams are the parameters used by the package-level Hash and
+// Verify helpers when no Params value is supplied.
+var DefaultParams = SHA256Params
+
+// Encoding selects how a salt or derived key is represented in the encoded
+// hash string. The zero value, EncodingRawStdB64, is the format this
+// package has always used, so hashes produced before Encoding existed keep
+// verifying without change.
+type Encoding int
+
+const (
+	// EncodingRawStdB64 is unpadded standard base64 (RFC 4648 alphabet).
+	EncodingRawStdB64 Encoding = iota
+	// EncodingStdB64 is padded standard base64.
+	EncodingStdB64
+	// EncodingHex is lowercase hexadecimal.
+	EncodingHex
+	// EncodingURLB64 is unpadded URL-safe base64.
+	EncodingURLB64
+	// EncodingUTF8 stores the bytes verbatim, with no transcoding. It
+	// exists for salts that are already printable text, as produced by
+	// systems like Django or Mosquitto. It is only valid for
+	// Params.SaltEncoding: a derived key is uniformly random binary data,
+	// and embedding it verbatim into the "$"-delimited hash string risks a
+	// stray "$" byte corrupting the format, so Params.Hash/HashContext
+	// reject Encoding: EncodingUTF8.
+	EncodingUTF8
+)
+
+// tag returns the string stamped into the encoded hash's parameter section
+// for e, or "" for the default, which is omitted to keep old hashes intact.
+func (e Encoding) tag() string {
+	switch e {
+	case EncodingStdB64:
+		return "std"
+	case EncodingHex:
+		return "hex"
+	case EncodingURLB64:
+		return "url"
+	case EncodingUTF8:
+		return "utf8"
+	default:
+		return ""
+	}
+}
+
+// encodingFromTag resolves a parameter-section tag back to an Encoding.
+func encodingFromTag(tag string) (Encoding, bool) {
+	switch tag {
+	case "":
+		return EncodingRawStdB64, true
+	case "std":
+		return EncodingStdB64, true
+	case "hex":
+		return EncodingHex, true
+	case "url":
+		return EncodingURLB64, true
+	case "utf8":
+		return EncodingUTF8, true
+	default:
+		return 0, false
+	}
+}
+
+// encode renders b using e.
+func (e Encoding) encode(b []byte) string {
+	switch e {
+	case EncodingStdB64:
+		return base64.StdEncoding.EncodeToString(b)
+	case EncodingHex:
+		return hex.EncodeToString(b)
+	case EncodingURLB64:
+		return base64.RawURLEncoding.EncodeToString(b)
+	case EncodingUTF8:
+		return string(b)
+	default:
+		return base64.RawStdEncoding.EncodeToString(b)
+	}
+}
+
+// decode parses s using e.
+func (e Encoding) decode(s string) ([]byte, error) {
+	switch e {
+	case EncodingStdB64:
+		return base64.StdEncoding.DecodeString(s)
+	case EncodingHex:
+		return hex.DecodeString(s)
+	case EncodingURLB64:
+		return base64.RawURLEncoding.DecodeString(s)
+	case EncodingUTF8:
+		return []byte(s), nil
+	default:
+		return base64.RawStdEncoding.DecodeString(s)
+	}
+}
+
 // Params configures the PBKDF2 hashing.
 type Params struct {
-	Iterations int
-	KeyLen     int
-	SaltLen    int
-	HashFunc   func() hash.Hash
+	Iterations     int
+	KeyLen         int
+	SaltLen        int
+	HashFunc       func() hash.Hash
+	Encoding       Encoding // how the derived key is encoded; defaults to EncodingRawStdB64
+	SaltEncoding   Encoding // how the salt is encoded; defaults to EncodingRawStdB64
+	MaxPasswordLen int      // cap used by HashReader; defaults to DefaultMaxPasswordLen
 }
 
 // GenerateSalt generates a random salt of the specified length.
@@ -66,6 +246,29 @@ func GenerateSalt(length int) ([]byte, error) {
 	return salt, nil
 }
 
+// alphanumericSaltAlphabet is the character set used by
+// GenerateAlphanumericSalt.
+const alphanumericSaltAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+// GenerateAlphanumericSalt generates a random salt of the specified length
+// made up only of ASCII letters and digits. Use it with EncodingUTF8, since
+// that encoding embeds the salt verbatim into the "$"-delimited hash string
+// and arbitrary bytes from GenerateSalt would not survive that round-trip.
+func GenerateAlphanumericSalt(length int) ([]byte, error) {
+	if length <= 0 {
+		return nil, errors.New("salt length must be positive")
+	}
+	raw := make([]byte, length)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, length)
+	for i, b := range raw {
+		salt[i] = alphanumericSaltAlphabet[int(b)%len(alphanumericSaltAlphabet)]
+	}
+	return salt, nil
+}
+
 // Hash hashes a password using PBKDF2 with the default parameters.
 // It returns a formatted string: $pbkdf2-sha256$i=<iterations>,l=<keyLen>$<base64Salt>$<base64Hash>
 func Hash(password []byte) (string, error) {
@@ -75,49 +278,48 @@ func Hash(password []byte) (string, error) {
 // Hash hashes a password using the configured parameters.
 // If any parameter is 0 (or nil for HashFunc), the value from DefaultParams is used.
 func (p Params) Hash(password []byte) (string, error) {
-	// Apply defaults for zero values
-	if p.Iterations == 0 {
-		p.Iterations = DefaultParams.Iterations
-	}
-	if p.KeyLen == 0 {
-		p.KeyLen = DefaultParams.KeyLen
-	}
-	if p.SaltLen == 0 {
-		p.SaltLen = DefaultParams.SaltLen
-	}
-	if p.HashFunc == nil {
-		p.HashFunc = DefaultParams.HashFunc
-	}
-
-	salt, err := GenerateSalt(p.SaltLen)
-	if err != nil {
-		return "", err
-	}
-
-	dk := pbkdf2.Key(password, salt, p.Iterations, p.KeyLen, p.HashFunc)
+	return p.HashContext(context.Background(), password)
+}
 
-	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
-	b64Hash := base64.RawStdEncoding.EncodeToString(dk)
+// maxDecodedKeyLen bounds the "l=" parameter accepted from an encoded hash
+// string. Without a cap, a crafted hash like "$pbkdf2-sha256$i=1,l=2000000000$..."
+// would force VerifyContext to allocate a multi-gigabyte buffer (or panic
+// with "makeslice: len out of range") before pbkdf2Chunked's per-chunk
+// ctx check ever runs, since the allocation happens up front regardless of
+// iterations. 1 MiB comfortably covers every real KeyLen this package's own
+// Params produce, including disk-encryption-key-sized derivations.
+const maxDecodedKeyLen = 1 << 20
 
-	// Format: $pbkdf2-sha256$i=<iterations>,l=<keyLen>$<base64Salt>$<base64Hash>
-	// Note: This library currently only supports SHA-256, which is hardcoded in the
-	// format string and Verify function. While the Params struct allows setting HashFunc,
-	// only SHA-256 should be used for compatibility.
-	return fmt.Sprintf("$pbkdf2-sha256$i=%d,l=%d$%s$%s", p.Iterations, p.KeyLen, b64Salt, b64Hash), nil
+// decodedHash holds the pieces parsed out of an encoded hash string.
+type decodedHash struct {
+	tag          string
+	hashFunc     func() hash.Hash
+	iterations   int
+	keyLen       int
+	encoding     Encoding
+	saltEncoding Encoding
+	salt         []byte
+	key          []byte
 }
 
-// Verify checks if a password matches the encoded hash.
-func Verify(password []byte, encodedHash string) (bool, error) {
+// decodeHash parses an encoded hash string produced by Params.Hash. Every
+// failure is reported as the same generic error so Verify and Cost never
+// leak which part of the string was malformed.
+func decodeHash(encodedHash string) (decodedHash, error) {
+	var d decodedHash
+
 	parts := strings.Split(encodedHash, "$")
 	if len(parts) != 5 {
-		return false, errors.New("invalid or corrupted hash")
+		return decodedHash{}, errors.New("invalid or corrupted hash")
 	}
 
-	if parts[1] != "pbkdf2-sha256" {
-		return false, errors.New("invalid or corrupted hash")
+	hashFunc, ok := lookupHash(parts[1])
+	if !ok {
+		return decodedHash{}, errors.New("invalid or corrupted hash")
 	}
+	d.tag = parts[1]
+	d.hashFunc = hashFunc
 
-	var iterations, keyLen int
 	params := strings.Split(parts[2], ",")
 	for _, param := range params {
 		kv := strings.Split(param, "=")
@@ -127,37 +329,158 @@ func Verify(password []byte, encodedHash string) (bool, error) {
 		var err error
 		switch kv[0] {
 		case "i":
-			iterations, err = strconv.Atoi(kv[1])
+			d.iterations, err = strconv.Atoi(kv[1])
 			if err != nil {
-				return false, errors.New("invalid or corrupted hash")
+				return decodedHash{}, errors.New("invalid or corrupted hash")
 			}
 		case "l":
-			keyLen, err = strconv.Atoi(kv[1])
+			d.keyLen, err = strconv.Atoi(kv[1])
 			if err != nil {
-				return false, errors.New("invalid or corrupted hash")
+				return decodedHash{}, errors.New("invalid or corrupted hash")
+			}
+		case "e":
+			d.encoding, ok = encodingFromTag(kv[1])
+			if !ok {
+				return decodedHash{}, errors.New("invalid or corrupted hash")
+			}
+		case "se":
+			d.saltEncoding, ok = encodingFromTag(kv[1])
+			if !ok {
+				return decodedHash{}, errors.New("invalid or corrupted hash")
 			}
 		}
 	}
 
-	if iterations == 0 || keyLen == 0 {
-		return false, errors.New("invalid or corrupted hash")
+	if d.iterations == 0 || d.keyLen == 0 || d.keyLen > maxDecodedKeyLen {
+		return decodedHash{}, errors.New("invalid or corrupted hash")
 	}
 
-	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	salt, err := d.saltEncoding.decode(parts[3])
 	if err != nil {
-		return false, errors.New("invalid or corrupted hash")
+		return decodedHash{}, errors.New("invalid or corrupted hash")
 	}
+	d.salt = salt
 
-	decodedHash, err := base64.RawStdEncoding.DecodeString(parts[4])
+	key, err := d.encoding.decode(parts[4])
 	if err != nil {
-		return false, errors.New("invalid or corrupted hash")
+		return decodedHash{}, errors.New("invalid or corrupted hash")
 	}
+	d.key = key
 
-	dk := pbkdf2.Key(password, salt, iterations, keyLen, sha256.New)
+	return d, nil
+}
 
-	if subtle.ConstantTimeCompare(dk, decodedHash) == 1 {
+// Verify checks if a password matches the encoded hash.
+func Verify(password []byte, encodedHash string) (bool, error) {
+	return VerifyContext(context.Background(), password, encodedHash)
+}
+
+// algorithmStrength orders the built-in algorithms from weakest to
+// strongest, by HMAC digest size, for use by NeedsRehash. Algorithms
+// registered via RegisterHash are not ranked and are always treated as at
+// least as strong as any built-in algorithm; look them up with
+// algorithmRank, not this map directly, since a missing key's zero value
+// would rank them weaker than everything instead.
+var algorithmStrength = map[string]int{
+	"pbkdf2-sha1":   1,
+	"pbkdf2-sha256": 2,
+	"pbkdf2-sha384": 3,
+	"pbkdf2-sha512": 4,
+}
+
+// algorithmRank reports tag's position in algorithmStrength, or
+// math.MaxInt for an unranked (custom-registered) tag, so it always
+// compares as at least as strong as any built-in algorithm.
+func algorithmRank(tag string) int {
+	if rank, ok := algorithmStrength[tag]; ok {
+		return rank
+	}
+	return math.MaxInt
+}
+
+// Cost parses encodedHash and reports the parameters it was hashed with, so
+// callers can decide whether it should be upgraded without knowing the
+// plaintext password. The returned Params.SaltLen reflects the decoded
+// salt's length, not a randomly generated one.
+func Cost(encodedHash string) (Params, error) {
+	d, err := decodeHash(encodedHash)
+	if err != nil {
+		return Params{}, err
+	}
+
+	return Params{
+		Iterations:   d.iterations,
+		KeyLen:       d.keyLen,
+		SaltLen:      len(d.salt),
+		HashFunc:     d.hashFunc,
+		Encoding:     d.encoding,
+		SaltEncoding: d.saltEncoding,
+	}, nil
+}
+
+// NeedsRehash reports whether encodedHash was produced with parameters
+// weaker than target: fewer iterations, a shorter key or salt, or a weaker
+// algorithm. It mirrors bcrypt.Cost-style rehash checks, but compares every
+// tunable parameter rather than a single cost factor.
+func NeedsRehash(encodedHash string, target Params) (bool, error) {
+	cur, err := Cost(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	if target.Iterations == 0 {
+		target.Iterations = DefaultParams.Iterations
+	}
+	if target.KeyLen == 0 {
+		target.KeyLen = DefaultParams.KeyLen
+	}
+	if target.SaltLen == 0 {
+		target.SaltLen = DefaultParams.SaltLen
+	}
+	if target.HashFunc == nil {
+		target.HashFunc = DefaultParams.HashFunc
+	}
+
+	if cur.Iterations < target.Iterations {
+		return true, nil
+	}
+	if cur.KeyLen < target.KeyLen {
+		return true, nil
+	}
+	if cur.SaltLen < target.SaltLen {
+		return true, nil
+	}
+
+	curTag, _ := tagForHash(cur.HashFunc)
+	targetTag, _ := tagForHash(target.HashFunc)
+	if algorithmRank(curTag) < algorithmRank(targetTag) {
 		return true, nil
 	}
 
 	return false, nil
 }
+
+// VerifyAndRehash verifies password against encoded and, if it matches but
+// was hashed with parameters weaker than target, also returns a freshly
+// computed hash so the caller can atomically upgrade the stored credential.
+// newHash is empty whenever ok is false or no upgrade is needed.
+func VerifyAndRehash(password []byte, encoded string, target Params) (ok bool, newHash string, err error) {
+	ok, err = Verify(password, encoded)
+	if err != nil || !ok {
+		return ok, "", err
+	}
+
+	needsRehash, err := NeedsRehash(encoded, target)
+	if err != nil {
+		return ok, "", err
+	}
+	if !needsRehash {
+		return ok, "", nil
+	}
+
+	newHash, err = target.Hash(password)
+	if err != nil {
+		return ok, "", err
+	}
+	return ok, newHash, nil
+}
@@ -23,6 +23,10 @@
 package pbkdf
 
 import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"strings"
 	"testing"
 )
@@ -64,6 +68,13 @@ func TestVerifyInvalidHash(t *testing.T) {
 	}
 }
 
+func TestVerifyRejectsOversizedKeyLen(t *testing.T) {
+	_, err := Verify([]byte("pass"), "$pbkdf2-sha256$i=1,l=2000000000$AAAA$AAAA")
+	if err == nil {
+		t.Error("Expected error for a hash string with an oversized l= parameter")
+	}
+}
+
 func TestCustomParams(t *testing.T) {
 	password := []byte("custom")
 	// Use partial params, expecting defaults for 0 values
@@ -124,6 +135,33 @@ func TestGenerateSaltInvalidLength(t *testing.T) {
 	}
 }
 
+func TestGenerateAlphanumericSalt(t *testing.T) {
+	salt, err := GenerateAlphanumericSalt(16)
+	if err != nil {
+		t.Fatalf("GenerateAlphanumericSalt failed: %v", err)
+	}
+	if len(salt) != 16 {
+		t.Errorf("GenerateAlphanumericSalt returned length %d, want 16", len(salt))
+	}
+	for _, b := range salt {
+		if !strings.ContainsRune(alphanumericSaltAlphabet, rune(b)) {
+			t.Fatalf("GenerateAlphanumericSalt returned non-alphanumeric byte %q", b)
+		}
+	}
+}
+
+func TestGenerateAlphanumericSaltInvalidLength(t *testing.T) {
+	_, err := GenerateAlphanumericSalt(0)
+	if err == nil {
+		t.Error("Expected error for zero salt length")
+	}
+
+	_, err = GenerateAlphanumericSalt(-1)
+	if err == nil {
+		t.Error("Expected error for negative salt length")
+	}
+}
+
 func TestVerifyMalformedParameters(t *testing.T) {
 	tests := []struct {
 		name string
@@ -179,3 +217,304 @@ func TestVerifyMalformedBase64(t *testing.T) {
 		})
 	}
 }
+
+func TestSHA512Params(t *testing.T) {
+	password := []byte("securepassword")
+
+	hash, err := SHA512Params.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$pbkdf2-sha512$") {
+		t.Errorf("Invalid hash format: %s", hash)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for correct password")
+	}
+}
+
+func TestVerifyUnknownAlgorithmTag(t *testing.T) {
+	_, err := Verify([]byte("password"), "$pbkdf2-blake2b$i=120000,l=32$dGVzdHNhbHQxMjM0NTY$dGVzdGhhc2gxMjM0NTY3ODkwMTIzNDU2Nzg5MDEy")
+	if err == nil {
+		t.Error("Expected error for unknown algorithm tag")
+	}
+	if err.Error() != "invalid or corrupted hash" {
+		t.Errorf("Expected generic error message, got: %v", err)
+	}
+}
+
+func TestCost(t *testing.T) {
+	password := []byte("securepassword")
+	params := Params{Iterations: 5000, KeyLen: 32, SaltLen: 16, HashFunc: sha512.New}
+
+	encoded, err := params.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	cost, err := Cost(encoded)
+	if err != nil {
+		t.Fatalf("Cost failed: %v", err)
+	}
+	if cost.Iterations != 5000 {
+		t.Errorf("Cost.Iterations = %d, want 5000", cost.Iterations)
+	}
+	if cost.KeyLen != 32 {
+		t.Errorf("Cost.KeyLen = %d, want 32", cost.KeyLen)
+	}
+	if cost.SaltLen != 16 {
+		t.Errorf("Cost.SaltLen = %d, want 16", cost.SaltLen)
+	}
+}
+
+func TestCostInvalidHash(t *testing.T) {
+	_, err := Cost("invalid")
+	if err == nil {
+		t.Error("Expected error for invalid hash format")
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	password := []byte("securepassword")
+	weak := Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha256.New}
+
+	encoded, err := weak.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	needs, err := NeedsRehash(encoded, SHA256Params)
+	if err != nil {
+		t.Fatalf("NeedsRehash failed: %v", err)
+	}
+	if !needs {
+		t.Error("NeedsRehash returned false for weaker parameters")
+	}
+
+	needs, err = NeedsRehash(encoded, weak)
+	if err != nil {
+		t.Fatalf("NeedsRehash failed: %v", err)
+	}
+	if needs {
+		t.Error("NeedsRehash returned true for matching parameters")
+	}
+
+	needs, err = NeedsRehash(encoded, Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha512.New})
+	if err != nil {
+		t.Fatalf("NeedsRehash failed: %v", err)
+	}
+	if !needs {
+		t.Error("NeedsRehash returned false for a weaker algorithm")
+	}
+}
+
+func TestNeedsRehashUnrankedAlgorithm(t *testing.T) {
+	RegisterHash("pbkdf2-custom-unranked", md5.New)
+	password := []byte("securepassword")
+
+	custom := Params{Iterations: 120000, KeyLen: 32, SaltLen: 16, HashFunc: md5.New}
+	encodedCustom, err := custom.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	weakBuiltinTarget := Params{Iterations: 1000, KeyLen: 16, SaltLen: 8, HashFunc: sha1.New}
+	needs, err := NeedsRehash(encodedCustom, weakBuiltinTarget)
+	if err != nil {
+		t.Fatalf("NeedsRehash failed: %v", err)
+	}
+	if needs {
+		t.Error("NeedsRehash flagged an unranked algorithm as weaker than a weaker built-in target")
+	}
+
+	strongBuiltin := Params{Iterations: 120000, KeyLen: 32, SaltLen: 16, HashFunc: sha512.New}
+	encodedBuiltin, err := strongBuiltin.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	needs, err = NeedsRehash(encodedBuiltin, custom)
+	if err != nil {
+		t.Fatalf("NeedsRehash failed: %v", err)
+	}
+	if !needs {
+		t.Error("NeedsRehash did not flag a built-in algorithm as needing an upgrade to an unranked target")
+	}
+}
+
+func TestVerifyAndRehash(t *testing.T) {
+	password := []byte("securepassword")
+	weak := Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha256.New}
+
+	encoded, err := weak.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	ok, newHash, err := VerifyAndRehash(password, encoded, SHA256Params)
+	if err != nil {
+		t.Fatalf("VerifyAndRehash failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("VerifyAndRehash returned ok=false for correct password")
+	}
+	if newHash == "" {
+		t.Error("Expected a rehashed value for weaker stored parameters")
+	}
+
+	match, err := Verify(password, newHash)
+	if err != nil {
+		t.Fatalf("Verify of rehashed value failed: %v", err)
+	}
+	if !match {
+		t.Error("Rehashed value does not verify against the original password")
+	}
+
+	ok, newHash, err = VerifyAndRehash([]byte("wrongpassword"), encoded, SHA256Params)
+	if err != nil {
+		t.Fatalf("VerifyAndRehash failed: %v", err)
+	}
+	if ok {
+		t.Error("VerifyAndRehash returned ok=true for wrong password")
+	}
+	if newHash != "" {
+		t.Error("Expected no rehash for a failed verification")
+	}
+}
+
+func TestHashEncodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding Encoding
+	}{
+		{"raw std b64", EncodingRawStdB64},
+		{"std b64", EncodingStdB64},
+		{"hex", EncodingHex},
+		{"url b64", EncodingURLB64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := []byte("securepassword")
+			params := Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha256.New, Encoding: tt.encoding}
+
+			hash, err := params.Hash(password)
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+
+			match, err := Verify(password, hash)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if !match {
+				t.Error("Verify returned false for correct password")
+			}
+		})
+	}
+}
+
+func TestSaltEncodingUTF8(t *testing.T) {
+	password := []byte("securepassword")
+	params := Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha256.New, SaltEncoding: EncodingUTF8}
+
+	hash, err := params.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if !strings.Contains(hash, "se=utf8") {
+		t.Errorf("Hash did not tag the salt encoding: %s", hash)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for correct password")
+	}
+
+	cost, err := Cost(hash)
+	if err != nil {
+		t.Fatalf("Cost failed: %v", err)
+	}
+	if cost.SaltEncoding != EncodingUTF8 {
+		t.Errorf("Cost.SaltEncoding = %v, want EncodingUTF8", cost.SaltEncoding)
+	}
+}
+
+func TestHashRejectsUTF8KeyEncoding(t *testing.T) {
+	params := Params{Iterations: 1000, KeyLen: 32, SaltLen: 16, HashFunc: sha256.New, Encoding: EncodingUTF8}
+
+	_, err := params.Hash([]byte("securepassword"))
+	if err == nil {
+		t.Error("Expected error for Encoding: EncodingUTF8, since a derived key isn't printable text")
+	}
+}
+
+func TestVerifyDefaultEncodingUnaffected(t *testing.T) {
+	password := []byte("securepassword")
+
+	hash, err := Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if strings.Contains(hash, "e=") {
+		t.Errorf("Default encoding should not be stamped into the hash: %s", hash)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for correct password")
+	}
+}
+
+func TestRegisterHash(t *testing.T) {
+	RegisterHash("pbkdf2-sha512-256", sha512.New512_256)
+
+	params := Params{HashFunc: sha512.New512_256}
+	password := []byte("custom-algorithm")
+
+	hash, err := params.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	if !strings.HasPrefix(hash, "$pbkdf2-sha512-256$") {
+		t.Errorf("Invalid hash format: %s", hash)
+	}
+
+	match, err := Verify(password, hash)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !match {
+		t.Error("Verify returned false for correct password")
+	}
+}
+
+func TestTagForHashAliasedConstructorIsDeterministic(t *testing.T) {
+	RegisterHash("pbkdf2-sha512-alias", sha512.New)
+
+	params := Params{HashFunc: sha512.New}
+	password := []byte("aliased-algorithm")
+
+	for i := 0; i < 20; i++ {
+		hash, err := params.Hash(password)
+		if err != nil {
+			t.Fatalf("Hash failed: %v", err)
+		}
+		if !strings.HasPrefix(hash, "$pbkdf2-sha512$") {
+			t.Errorf("Hash stamped tag %q, want the earlier-registered \"pbkdf2-sha512\" every time", hash)
+		}
+	}
+}